@@ -0,0 +1,151 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// bindTags lists the struct tags bindRequest understands, in the order they
+// are checked. "body" is handled separately since it decodes a sub-value
+// rather than coercing a single string
+var bindTags = []string{"path", "query", "header", "form"}
+
+// Binder extracts the raw string value for key from r, reporting whether it
+// was present
+type Binder func(r *http.Request, key string) (value string, ok bool)
+
+// Binders maps a struct tag name (path, query, header, form) to the Binder
+// used to resolve it. Register additional sources, or override the built-in
+// ones, by assigning into this map
+var Binders = map[string]Binder{
+	"path":   pathBinder,
+	"query":  queryBinder,
+	"header": headerBinder,
+	"form":   formBinder,
+}
+
+func pathBinder(r *http.Request, key string) (string, bool) {
+	v, ok := mux.Vars(r)[key]
+	return v, ok
+}
+
+func queryBinder(r *http.Request, key string) (string, bool) {
+	q := r.URL.Query()
+	if !q.Has(key) {
+		return "", false
+	}
+	return q.Get(key), true
+}
+
+func headerBinder(r *http.Request, key string) (string, bool) {
+	v := r.Header.Get(key)
+	return v, v != ""
+}
+
+func formBinder(r *http.Request, key string) (string, bool) {
+	if err := r.ParseForm(); err != nil {
+		return "", false
+	}
+	if !r.Form.Has(key) {
+		return "", false
+	}
+	return r.Form.Get(key), true
+}
+
+// hasBindTags reports whether t declares any of bindTags or a "body" tag, in
+// which case decodeReq binds the request field-by-field instead of decoding
+// the whole body into it with the request's codec
+func hasBindTags(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if _, ok := f.Tag.Lookup("body"); ok {
+			return true
+		}
+
+		for _, tag := range bindTags {
+			if _, ok := f.Tag.Lookup(tag); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// bindRequest populates req's fields from path, query, header and form
+// values, and decodes a "body" tagged field with codec, per the struct tags
+// declared on its type
+func bindRequest(r *http.Request, codec Codec, req interface{}) error {
+	v := reflect.ValueOf(req).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := v.Field(i)
+
+		if _, ok := f.Tag.Lookup("body"); ok {
+			if err := codec.Decode(r.Body, fv.Addr().Interface()); err != nil {
+				return fmt.Errorf("field %q: could not decode body: %v", f.Name, err)
+			}
+			continue
+		}
+
+		for _, tagName := range bindTags {
+			key, ok := f.Tag.Lookup(tagName)
+			if !ok {
+				continue
+			}
+
+			value, found := Binders[tagName](r, key)
+			if !found {
+				if f.Tag.Get("required") == "true" {
+					return fmt.Errorf("field %q: required %s %q is missing", f.Name, tagName, key)
+				}
+				continue
+			}
+
+			if err := setFieldValue(fv, value); err != nil {
+				return fmt.Errorf("field %q: %v", f.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func setFieldValue(fv reflect.Value, value string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("could not coerce %q to int: %v", value, err)
+		}
+		fv.SetInt(n)
+	case reflect.Bool:
+		bv, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("could not coerce %q to bool: %v", value, err)
+		}
+		fv.SetBool(bv)
+	case reflect.Float32, reflect.Float64:
+		fl, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("could not coerce %q to float: %v", value, err)
+		}
+		fv.SetFloat(fl)
+	default:
+		return fmt.Errorf("unsupported field type %s for binding", fv.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+type boundReq struct {
+	ID     string `path:"id"`
+	Filter string `query:"filter"`
+	Token  string `header:"X-Token" required:"true"`
+	Body   struct {
+		Name string `json:"name"`
+	} `body:"json"`
+}
+
+func TestBindRequestBindsAllSources(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/things/42?filter=active", strings.NewReader(`{"name":"kit"}`))
+	r.Header.Set("X-Token", "secret")
+	r = mux.SetURLVars(r, map[string]string{"id": "42"})
+
+	var req boundReq
+	if err := bindRequest(r, JSONCodec, &req); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.ID != "42" || req.Filter != "active" || req.Token != "secret" || req.Body.Name != "kit" {
+		t.Fatalf("unexpected bound request: %+v", req)
+	}
+}
+
+func TestBindRequestRequiredFieldMissing(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/things/42", strings.NewReader(`{"name":"kit"}`))
+	r = mux.SetURLVars(r, map[string]string{"id": "42"})
+
+	var req boundReq
+	if err := bindRequest(r, JSONCodec, &req); err == nil {
+		t.Fatal("expected error for missing required header")
+	}
+}
+
+type coercedReq struct {
+	Count int `query:"count"`
+}
+
+func TestBindRequestCoercionError(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/things?count=notanumber", nil)
+
+	var req coercedReq
+	if err := bindRequest(r, JSONCodec, &req); err == nil {
+		t.Fatal("expected coercion error for non-numeric count")
+	}
+}
+
+func TestJSONCodecRejectsUnknownFields(t *testing.T) {
+	var v struct {
+		Name string `json:"name"`
+	}
+	err := JSONCodec.Decode(strings.NewReader(`{"name":"kit","extra":"nope"}`), &v)
+	if err == nil {
+		t.Fatal("expected error decoding unknown field")
+	}
+}
+
+func TestFormBinder(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(url.Values{"name": {"kit"}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	v, ok := formBinder(r, "name")
+	if !ok || v != "kit" {
+		t.Fatalf("expected form value kit, got %q (ok=%v)", v, ok)
+	}
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"strings"
+)
+
+// Codec encodes and decodes endpoint request/response payloads for a given
+// wire format
+type Codec interface {
+	// Name returns the codec's canonical name (e.g. "json")
+	Name() string
+	// ContentTypes returns the MIME types this codec should be selected for
+	ContentTypes() []string
+	// Decode reads a single value from r into v
+	Decode(r io.Reader, v interface{}) error
+	// Encode writes v to w
+	Encode(w io.Writer, v interface{}) error
+}
+
+// Codecs is a registry of Codec, keyed by the content types it handles
+type Codecs map[string]Codec
+
+// Register adds c to the registry under each of its ContentTypes
+func (cs Codecs) Register(c Codec) {
+	for _, ct := range c.ContentTypes() {
+		cs[ct] = c
+	}
+}
+
+type jsonCodec struct{}
+
+// JSONCodec is the Codec BaseService decodes requests with when they carry no
+// Content-Type (an unregistered Content-Type is rejected), and encodes
+// responses with when neither the Accept header nor the request's codec
+// selects another
+var JSONCodec Codec = jsonCodec{}
+
+func (jsonCodec) Name() string           { return "json" }
+func (jsonCodec) ContentTypes() []string { return []string{"application/json"} }
+
+// Decode rejects fields in r that don't map to v, surfacing them as a decode
+// error (decodeReq/bindRequest wrap this as a 400 for request bodies). Extra
+// query/header/form values are intentionally left alone by bindRequest, since
+// tolerating unrecognised parameters is normal REST behaviour; only the
+// request body is held to strict field matching
+func (jsonCodec) Decode(r io.Reader, v interface{}) error {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+func (jsonCodec) Encode(w io.Writer, v interface{}) error { return json.NewEncoder(w).Encode(v) }
+
+// contentType strips parameters (e.g. "; charset=utf-8") off of a
+// Content-Type header value
+func contentType(h string) string {
+	if h == "" {
+		return ""
+	}
+	ct, _, err := mime.ParseMediaType(h)
+	if err != nil {
+		return strings.TrimSpace(strings.SplitN(h, ";", 2)[0])
+	}
+	return ct
+}
+
+// acceptedContentTypes returns the content types listed in an Accept header,
+// in the order they are listed; quality parameters are ignored and do not
+// reorder them
+func acceptedContentTypes(h string) []string {
+	var out []string
+	for _, p := range strings.Split(h, ",") {
+		if ct := contentType(strings.TrimSpace(p)); ct != "" {
+			out = append(out, ct)
+		}
+	}
+	return out
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type upperCodec struct{}
+
+func (upperCodec) Name() string           { return "upper" }
+func (upperCodec) ContentTypes() []string { return []string{"application/x-upper"} }
+func (upperCodec) Decode(r io.Reader, v interface{}) error {
+	return JSONCodec.Decode(r, v)
+}
+func (upperCodec) Encode(w io.Writer, v interface{}) error {
+	return JSONCodec.Encode(w, v)
+}
+
+type echoReq struct {
+	Name string `json:"name"`
+}
+
+func echoHandler(c context.Context, w http.ResponseWriter, req *echoReq) (*Response, error) {
+	return NewResponse(req.Name, http.StatusOK), nil
+}
+
+func TestResponseCodecPrefersAccept(t *testing.T) {
+	var svc BaseService
+	svc.RegisterCodec(upperCodec{})
+	if err := svc.RegisterEndpoint(http.MethodPost, "/echo", echoHandler); err != nil {
+		t.Fatal(err)
+	}
+
+	h := svc.Endpoints()["/echo"].Handler
+
+	r := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewReader([]byte(`{"name":"kit"}`)))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Accept", "application/x-upper")
+	w := httptest.NewRecorder()
+	h(context.Background(), w, r)
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-upper" {
+		t.Fatalf("expected response Content-Type application/x-upper, got %q", ct)
+	}
+}
+
+func TestResponseCodecFallsBackToRequestCodec(t *testing.T) {
+	var svc BaseService
+	svc.RegisterCodec(upperCodec{})
+	if err := svc.RegisterEndpoint(http.MethodPost, "/echo", echoHandler); err != nil {
+		t.Fatal(err)
+	}
+
+	h := svc.Endpoints()["/echo"].Handler
+
+	r := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewReader([]byte(`{"name":"kit"}`)))
+	r.Header.Set("Content-Type", "application/x-upper")
+	w := httptest.NewRecorder()
+	h(context.Background(), w, r)
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-upper" {
+		t.Fatalf("expected response Content-Type application/x-upper, got %q", ct)
+	}
+}
+
+func TestDecodeReqRejectsUnsupportedContentType(t *testing.T) {
+	var svc BaseService
+	if err := svc.RegisterEndpoint(http.MethodPost, "/echo", echoHandler); err != nil {
+		t.Fatal(err)
+	}
+
+	h := svc.Endpoints()["/echo"].Handler
+
+	r := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewReader([]byte(`{"name":"kit"}`)))
+	r.Header.Set("Content-Type", "application/x-unknown")
+	w := httptest.NewRecorder()
+	h(context.Background(), w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d for unsupported content type, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestContentTypeStripsParameters(t *testing.T) {
+	if ct := contentType("application/json; charset=utf-8"); ct != "application/json" {
+		t.Fatalf("expected application/json, got %q", ct)
+	}
+}
+
+func TestAcceptedContentTypesOrder(t *testing.T) {
+	got := acceptedContentTypes("application/x-upper, application/json;q=0.8")
+	if len(got) != 2 || got[0] != "application/x-upper" || got[1] != "application/json" {
+		t.Fatalf("unexpected order: %v", got)
+	}
+}
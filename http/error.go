@@ -0,0 +1,100 @@
+package http
+
+import "net/http"
+
+// ErrorCode identifies a class of error independent of the HTTP status used
+// to report it, so callers can errors.Is/errors.As against a stable value
+// instead of a raw status code
+type ErrorCode string
+
+var errorCodeStatus = map[ErrorCode]int{}
+
+// RegisterErrorCode associates code with the HTTP status NewCodedError should
+// default to when rendering it
+func RegisterErrorCode(code ErrorCode, status int) { errorCodeStatus[code] = status }
+
+// Status returns the HTTP status registered for c via RegisterErrorCode, or
+// http.StatusInternalServerError if none was registered
+func (c ErrorCode) Status() int {
+	if s, ok := errorCodeStatus[c]; ok {
+		return s
+	}
+	return http.StatusInternalServerError
+}
+
+// Error represents an endpoint error response
+type Error struct {
+	Status  int                    `json:"-"`
+	Code    ErrorCode              `json:"code,omitempty"`
+	Message string                 `json:"message"`
+	Detail  map[string]interface{} `json:"detail,omitempty"`
+}
+
+// NewError returns a new *Error with the given status and underlying error message
+func NewError(status int, err error) *Error {
+	return &Error{Status: status, Message: err.Error()}
+}
+
+// NewCodedError returns a new *Error tagged with code and attaching detail, if
+// any. Its HTTP status is resolved from code's registered status (see
+// RegisterErrorCode) when the error is rendered, not here, so sentinel errors
+// declared as package vars (e.g. var ErrNotFound = NewCodedError(CodeNotFound, ...))
+// still pick up a status registered later by an init() func: package vars are
+// initialized before init() runs, so snapshotting code.Status() at this point
+// would see the code unregistered and bake in http.StatusInternalServerError
+func NewCodedError(code ErrorCode, message string, detail map[string]interface{}) *Error {
+	return &Error{Code: code, Message: message, Detail: detail}
+}
+
+// Error implements the error interface
+func (e *Error) Error() string { return e.Message }
+
+// Is allows sentinel *Error values to be matched with errors.Is by Code, so
+// services can declare e.g. var ErrNotFound = NewCodedError(CodeNotFound, ...)
+// and test returned errors against it
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && e.Code != "" && e.Code == t.Code
+}
+
+// StatusCode implements respond.Statuser. It returns the explicit Status set
+// via NewError, or resolves Code's registered status (see RegisterErrorCode)
+// for errors built with NewCodedError
+func (e *Error) StatusCode() int {
+	if e.Status != 0 {
+		return e.Status
+	}
+	if e.Code != "" {
+		return e.Code.Status()
+	}
+	return http.StatusInternalServerError
+}
+
+// errorEnvelope is the JSON shape written for errors raised through
+// RegisterErrorEndpoint: {"errors":[{"code":...,"message":...,"detail":...}]}
+type errorEnvelope struct {
+	Errors []*Error `json:"errors"`
+}
+
+func newErrorEnvelope(errs ...*Error) *errorEnvelope {
+	return &errorEnvelope{Errors: errs}
+}
+
+// StatusCode implements respond.Statuser
+func (e *errorEnvelope) StatusCode() int {
+	if len(e.Errors) == 0 {
+		return http.StatusInternalServerError
+	}
+	return e.Errors[0].StatusCode()
+}
+
+// RegisteredErrorCodes returns every ErrorCode registered via
+// RegisterErrorCode, in no particular order. OpenAPI uses this to enumerate
+// error responses for endpoints that don't declare WithErrorCodes
+func RegisteredErrorCodes() []ErrorCode {
+	codes := make([]ErrorCode, 0, len(errorCodeStatus))
+	for c := range errorCodeStatus {
+		codes = append(codes, c)
+	}
+	return codes
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const codeNotFound ErrorCode = "not_found"
+
+func init() {
+	RegisterErrorCode(codeNotFound, http.StatusNotFound)
+}
+
+// errNotFoundSentinel is declared as a package var built from NewCodedError,
+// mirroring the catalog pattern NewCodedError's doc describes. Since Go
+// initializes package vars before running init(), this is also a regression
+// test for resolving ErrorCode statuses at render time instead of baking them
+// in at construction time
+var errNotFoundSentinel = NewCodedError(codeNotFound, "not found", nil)
+
+type getThingReq struct {
+	ID string `json:"id"`
+}
+
+func getThing(c context.Context, w http.ResponseWriter, req *getThingReq) (*Response, error) {
+	if req.ID == "missing" {
+		return nil, errNotFoundSentinel
+	}
+	return NewResponse(req.ID, http.StatusOK), nil
+}
+
+func TestCodedErrorStatusResolvesAtRenderTime(t *testing.T) {
+	var svc BaseService
+	if err := svc.RegisterErrorEndpoint(http.MethodPost, "/thing", getThing); err != nil {
+		t.Fatal(err)
+	}
+
+	h := svc.Endpoints()["/thing"].Handler
+
+	r := httptest.NewRequest(http.MethodPost, "/thing", strings.NewReader(`{"id":"missing"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h(context.Background(), w, r)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+
+	var body errorEnvelope
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Errors) != 1 || body.Errors[0].Code != codeNotFound {
+		t.Fatalf("unexpected error envelope: %+v", body)
+	}
+}
+
+func TestErrorIsMatchesByCode(t *testing.T) {
+	err := NewCodedError(codeNotFound, "different message", nil)
+	if !errors.Is(err, errNotFoundSentinel) {
+		t.Fatal("expected errors.Is to match sentinel by code")
+	}
+}
+
+func TestRegisterErrorEndpointRejectsMalformedRawHandler(t *testing.T) {
+	var svc BaseService
+
+	// three params and an error return shape the old isRawErrorHandler check
+	// accepted on NumIn/NumOut/In(2)/Out(0) alone, but the first param isn't a
+	// context.Context, so this must fail registration instead of panicking in
+	// v.Call at request time
+	bad := func(n int, w http.ResponseWriter, r *http.Request) error { return nil }
+
+	if err := svc.RegisterErrorEndpoint(http.MethodGet, "/bad", bad); err == nil {
+		t.Fatal("expected registration to fail for malformed raw error handler")
+	}
+}
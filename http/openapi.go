@@ -0,0 +1,339 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// endpointDoc holds the OpenAPI documentation hints attached to an endpoint
+// via Document
+type endpointDoc struct {
+	summary    string
+	tags       []string
+	security   openapi3.SecurityRequirements
+	respType   reflect.Type
+	errorCodes []ErrorCode
+}
+
+// EndpointOption attaches OpenAPI documentation metadata to an endpoint via
+// Document
+type EndpointOption func(*endpointDoc)
+
+// WithSummary sets the endpoint's OpenAPI summary
+func WithSummary(s string) EndpointOption {
+	return func(d *endpointDoc) { d.summary = s }
+}
+
+// WithTags sets the endpoint's OpenAPI tags
+func WithTags(tags ...string) EndpointOption {
+	return func(d *endpointDoc) { d.tags = tags }
+}
+
+// WithSecurity attaches an OpenAPI security requirement naming a scheme
+// declared elsewhere in the document, e.g. WithSecurity("bearerAuth")
+func WithSecurity(name string, scopes ...string) EndpointOption {
+	return func(d *endpointDoc) {
+		d.security = append(d.security, openapi3.SecurityRequirement{name: scopes})
+	}
+}
+
+// WithResponseType derives the endpoint's 200 response schema from an example
+// value of the payload it wraps in a *Response. This has to be declared
+// explicitly: *Response.Payload is an interface{}, so the concrete payload
+// type a handler returns can't be recovered from its method signature by
+// reflection alone
+func WithResponseType(v interface{}) EndpointOption {
+	return func(d *endpointDoc) { d.respType = reflect.TypeOf(v) }
+}
+
+// WithErrorCodes declares the ErrorCodes an endpoint can return, so OpenAPI
+// can document one response per code (using its registered status, see
+// RegisterErrorCode) instead of a single generic "default" error response
+func WithErrorCodes(codes ...ErrorCode) EndpointOption {
+	return func(d *endpointDoc) { d.errorCodes = codes }
+}
+
+// Document attaches OpenAPI documentation metadata to the endpoint already
+// registered at path. It is a no-op if no endpoint has been registered there
+func (b *BaseService) Document(path string, opts ...EndpointOption) {
+	e, ok := b.endpoints[path]
+	if !ok {
+		return
+	}
+
+	if e.doc == nil {
+		e.doc = &endpointDoc{}
+	}
+
+	for _, opt := range opts {
+		opt(e.doc)
+	}
+}
+
+// OpenAPI walks all endpoints registered via RegisterEndpoint/RegisterErrorEndpoint
+// and builds an OpenAPI 3 document describing them: request body schemas are
+// derived from the endpoint's request struct via reflection and JSON tags,
+// the 200 response schema is derived from the type passed to WithResponseType
+// (falling back to a hollow object schema if none was declared, since a
+// handler's *Response return type carries no static payload type to
+// introspect), and error responses are one per ErrorCode declared via
+// WithErrorCodes, or every code registered with RegisterErrorCode if none
+// were declared, or the generic envelope if no codes are registered at all
+func (b *BaseService) OpenAPI() *openapi3.T {
+	doc := &openapi3.T{
+		OpenAPI: "3.0.0",
+		Info:    &openapi3.Info{Title: "API", Version: "1.0.0"},
+		Paths:   openapi3.Paths{},
+	}
+
+	for path, e := range b.endpoints {
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &openapi3.PathItem{}
+			doc.Paths[path] = item
+		}
+
+		op := &openapi3.Operation{
+			Responses: openapi3.NewResponses(),
+		}
+
+		if e.doc != nil {
+			op.Summary = e.doc.summary
+			op.Tags = e.doc.tags
+			if len(e.doc.security) > 0 {
+				sec := e.doc.security
+				op.Security = &sec
+			}
+		}
+
+		if e.reqType != nil {
+			op.RequestBody = &openapi3.RequestBodyRef{
+				Value: openapi3.NewRequestBody().WithJSONSchema(schemaFromStruct(e.reqType)),
+			}
+		}
+
+		respSchema := openapi3.NewObjectSchema()
+		if e.doc != nil && e.doc.respType != nil {
+			respSchema = schemaForType(e.doc.respType)
+		}
+		op.Responses["200"] = &openapi3.ResponseRef{
+			Value: openapi3.NewResponse().WithDescription("OK").WithJSONSchema(respSchema),
+		}
+
+		var codes []ErrorCode
+		if e.doc != nil {
+			codes = e.doc.errorCodes
+		}
+		if len(codes) == 0 {
+			codes = RegisteredErrorCodes()
+		}
+		if len(codes) == 0 {
+			op.Responses["default"] = &openapi3.ResponseRef{
+				Value: openapi3.NewResponse().WithDescription("Error").WithJSONSchema(errorEnvelopeSchema()),
+			}
+		} else {
+			for _, code := range codes {
+				op.Responses[strconv.Itoa(code.Status())] = &openapi3.ResponseRef{
+					Value: openapi3.NewResponse().WithDescription(string(code)).WithJSONSchema(errorEnvelopeSchema()),
+				}
+			}
+		}
+
+		for _, verb := range e.Methods {
+			switch strings.ToUpper(verb) {
+			case http.MethodGet:
+				item.Get = op
+			case http.MethodPost:
+				item.Post = op
+			case http.MethodPut:
+				item.Put = op
+			case http.MethodPatch:
+				item.Patch = op
+			case http.MethodDelete:
+				item.Delete = op
+			}
+		}
+	}
+
+	return doc
+}
+
+// schemaFromStruct derives an OpenAPI object schema for t's exported fields,
+// using doc/example/enum struct-tag hints, and skipping fields bound from
+// path/query/header/form (see bind.go), since those aren't part of the JSON
+// body. If t declares a "body" tagged field (see bindRequest), that field's
+// own type is the JSON body and its schema is returned directly, rather than
+// nesting it as a sub-property of the wrapper struct
+func schemaFromStruct(t reflect.Type) *openapi3.Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return openapi3.NewSchema()
+	}
+
+	if bodyType, ok := bodyFieldType(t); ok {
+		return schemaFromStruct(bodyType)
+	}
+
+	schema := openapi3.NewObjectSchema()
+	schema.Properties = make(openapi3.Schemas)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		if isBoundField(f) {
+			continue
+		}
+
+		name := jsonFieldName(f)
+		if name == "-" {
+			continue
+		}
+
+		fs := schemaForType(f.Type)
+
+		if doc := f.Tag.Get("doc"); doc != "" {
+			fs.Description = doc
+		}
+		if ex := f.Tag.Get("example"); ex != "" {
+			fs.Example = ex
+		}
+		if enum := f.Tag.Get("enum"); enum != "" {
+			for _, v := range strings.Split(enum, ",") {
+				fs.Enum = append(fs.Enum, v)
+			}
+		}
+		if f.Tag.Get("required") == "true" {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = openapi3.NewSchemaRef("", fs)
+	}
+
+	return schema
+}
+
+// isBoundField reports whether f is populated by bindRequest (a "body" tag or
+// any of bindTags), rather than being part of the JSON body itself
+func isBoundField(f reflect.StructField) bool {
+	if _, ok := f.Tag.Lookup("body"); ok {
+		return true
+	}
+	for _, tag := range bindTags {
+		if _, ok := f.Tag.Lookup(tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyFieldType returns the type of t's "body" tagged field, if it declares
+// one
+func bodyFieldType(t reflect.Type) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("body"); ok {
+			return t.Field(i).Type, true
+		}
+	}
+	return nil, false
+}
+
+func jsonFieldName(f reflect.StructField) string {
+	tag := f.Tag.Get("json")
+	if tag == "" {
+		return f.Name
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name
+	}
+	return f.Name
+}
+
+func schemaForType(t reflect.Type) *openapi3.Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return openapi3.NewStringSchema()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return openapi3.NewIntegerSchema()
+	case reflect.Float32, reflect.Float64:
+		return openapi3.NewFloat64Schema()
+	case reflect.Bool:
+		return openapi3.NewBoolSchema()
+	case reflect.Slice, reflect.Array:
+		return openapi3.NewArraySchema().WithItems(schemaForType(t.Elem()))
+	case reflect.Struct:
+		return schemaFromStruct(t)
+	default:
+		return openapi3.NewSchema()
+	}
+}
+
+func errorEnvelopeSchema() *openapi3.Schema {
+	errSchema := openapi3.NewObjectSchema().WithProperties(map[string]*openapi3.Schema{
+		"code":    openapi3.NewStringSchema(),
+		"message": openapi3.NewStringSchema(),
+		"detail":  openapi3.NewObjectSchema(),
+	})
+	return openapi3.NewObjectSchema().WithProperty("errors", openapi3.NewArraySchema().WithItems(errSchema))
+}
+
+// OpenAPIService serves a generated OpenAPI 3 document at /openapi.json and a
+// Swagger-UI page at /docs. It is opt-in: register it alongside the services
+// whose OpenAPI() document it should serve
+type OpenAPIService struct {
+	BaseService
+}
+
+// NewOpenAPIService returns an opt-in service exposing doc at /openapi.json
+// and a Swagger UI at /docs
+func NewOpenAPIService(doc *openapi3.T) (*OpenAPIService, error) {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal openapi document: %v", err)
+	}
+
+	s := &OpenAPIService{}
+
+	s.RegisterHandler(http.MethodGet, "/openapi.json", func(c context.Context, w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(body)
+	})
+
+	s.RegisterHandler(http.MethodGet, "/docs", func(c context.Context, w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(swaggerUIPage))
+	})
+
+	return s, nil
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html>
+<head>
+<title>API docs</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist/swagger-ui-bundle.js"></script>
+<script>
+window.onload = () => SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" })
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+type thingResp struct {
+	ID string `json:"id"`
+}
+
+func getThingForDocs(c context.Context, w http.ResponseWriter, req *getThingReq) (*Response, error) {
+	return NewResponse(thingResp{ID: req.ID}, http.StatusOK), nil
+}
+
+func TestOpenAPIDerivesResponseAndErrorSchemas(t *testing.T) {
+	var svc BaseService
+	if err := svc.RegisterErrorEndpoint(http.MethodGet, "/thing", getThingForDocs); err != nil {
+		t.Fatal(err)
+	}
+	svc.Document("/thing", WithResponseType(thingResp{}), WithErrorCodes(codeNotFound))
+
+	doc := svc.OpenAPI()
+
+	op := doc.Paths["/thing"].Get
+	if op == nil {
+		t.Fatal("expected GET operation for /thing")
+	}
+
+	resp200 := op.Responses["200"]
+	if resp200 == nil || resp200.Value.Content == nil {
+		t.Fatal("expected 200 response with a JSON schema")
+	}
+	schema := resp200.Value.Content["application/json"].Schema.Value
+	if _, ok := schema.Properties["id"]; !ok {
+		t.Fatalf("expected 200 schema to have an id property derived from thingResp, got %+v", schema.Properties)
+	}
+
+	if _, ok := op.Responses["404"]; !ok {
+		t.Fatalf("expected a 404 response for the declared not_found error code, got %v", op.Responses)
+	}
+}
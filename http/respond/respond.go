@@ -0,0 +1,27 @@
+// Package respond provides helpers for writing endpoint results to an
+// http.ResponseWriter
+package respond
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Statuser can be implemented by a response value to report the HTTP status
+// code it should be written with
+type Statuser interface {
+	StatusCode() int
+}
+
+// WithJSON writes v to w as JSON, using v's StatusCode if it implements
+// Statuser, or http.StatusOK otherwise
+func WithJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	status := http.StatusOK
+	if s, ok := v.(Statuser); ok {
+		status = s.StatusCode()
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,15 @@
+package http
+
+// Response represents a standard endpoint response
+type Response struct {
+	Status  int         `json:"-"`
+	Payload interface{} `json:"payload,omitempty"`
+}
+
+// NewResponse returns a new *Response with the given payload and status code
+func NewResponse(payload interface{}, status int) *Response {
+	return &Response{Status: status, Payload: payload}
+}
+
+// StatusCode implements respond.Statuser
+func (r *Response) StatusCode() int { return r.Status }
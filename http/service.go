@@ -2,7 +2,7 @@ package http
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -17,11 +17,67 @@ type Validator interface {
 	Validate() error
 }
 
+// ErrorWriter writes err as the HTTP response for a failed RegisterErrorEndpoint
+// invocation. Services can override the default via SetErrorWriter to customize
+// how errors are rendered
+type ErrorWriter func(c context.Context, w http.ResponseWriter, r *http.Request, err error)
+
 // BaseService represents base http service
 type BaseService struct {
 	m         sync.Mutex
 	endpoints Endpoints
 	mw        []Adapter
+	errW      ErrorWriter
+	codecs    Codecs
+}
+
+// RegisterCodec adds c to the set of codecs the service uses to decode
+// requests (by Content-Type) and encode responses (by Accept). JSON is
+// available by default even if RegisterCodec is never called
+func (b *BaseService) RegisterCodec(c Codec) {
+	if b.codecs == nil {
+		b.codecs = Codecs{}
+		b.codecs.Register(JSONCodec)
+	}
+	b.codecs.Register(c)
+}
+
+func (b *BaseService) codecRegistry() Codecs {
+	if b.codecs == nil {
+		cs := Codecs{}
+		cs.Register(JSONCodec)
+		return cs
+	}
+	return b.codecs
+}
+
+// responseCodec picks the codec a response should be encoded with, preferring
+// the request's Accept header, falling back to reqCodec (the codec the
+// request body was decoded with, if any) and finally JSONCodec
+func (b *BaseService) responseCodec(r *http.Request, reqCodec Codec) Codec {
+	registry := b.codecRegistry()
+	for _, ct := range acceptedContentTypes(r.Header.Get("Accept")) {
+		if ct == "*/*" {
+			break
+		}
+		if c, ok := registry[ct]; ok {
+			return c
+		}
+	}
+	if reqCodec != nil {
+		return reqCodec
+	}
+	return JSONCodec
+}
+
+func (b *BaseService) write(w http.ResponseWriter, r *http.Request, codec Codec, v interface{}) {
+	status := http.StatusOK
+	if s, ok := v.(respond.Statuser); ok {
+		status = s.StatusCode()
+	}
+	w.Header().Set("Content-Type", codec.ContentTypes()[0])
+	w.WriteHeader(status)
+	codec.Encode(w, v)
 }
 
 // Prefix returns service routing prefix
@@ -65,22 +121,155 @@ func (b *BaseService) RegisterEndpoint(verb string, path string, method interfac
 	b.endpoints[path] = &Endpoint{
 		Methods: []string{verb},
 		Handler: AdaptHandlerFunc(h, a...),
+		reqType: reflect.ValueOf(method).Type().In(2).Elem(),
+	}
+
+	return nil
+}
+
+// RegisterErrorEndpoint is a helper method that registers a service endpoint
+// whose handler reports failure by returning a non-nil error instead of
+// writing it to w directly. Two handler signatures are supported:
+//
+//	func(c context.Context, w http.ResponseWriter, r *http.Request) error
+//	func(c context.Context, w http.ResponseWriter, req *CustomType) (*http.Response, error)
+//
+// In both cases a non-nil error short-circuits response writing and is handed
+// to the service's ErrorWriter (see SetErrorWriter) instead of being written
+// by the handler itself.
+func (b *BaseService) RegisterErrorEndpoint(verb string, path string, method interface{}, a ...Adapter) error {
+	h, err := b.handlerFromErrorMethod(method)
+	if err != nil {
+		return err
+	}
+
+	if b.endpoints == nil {
+		b.endpoints = make(map[string]*Endpoint)
+	}
+
+	e := &Endpoint{
+		Methods: []string{verb},
+		Handler: AdaptHandlerFunc(h, a...),
 	}
 
+	if t := reflect.ValueOf(method).Type(); isTypedErrorHandler(t) {
+		e.reqType = t.In(2).Elem()
+	}
+
+	b.endpoints[path] = e
+
 	return nil
 }
 
+// SetErrorWriter overrides the ErrorWriter used by RegisterErrorEndpoint
+// handlers. If never called, DefaultErrorWriter is used
+func (b *BaseService) SetErrorWriter(ew ErrorWriter) { b.errW = ew }
+
+func (b *BaseService) errorWriter() ErrorWriter {
+	if b.errW != nil {
+		return b.errW
+	}
+	return DefaultErrorWriter
+}
+
+// DefaultErrorWriter renders err as the standard {"errors":[...]} envelope,
+// coercing plain errors into an internal server *Error via errors.As
+func DefaultErrorWriter(c context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = NewError(http.StatusInternalServerError, err)
+	}
+	respond.WithJSON(w, r, newErrorEnvelope(e))
+}
+
+func (b *BaseService) handlerFromErrorMethod(m interface{}) (HandlerFunc, error) {
+	t := reflect.ValueOf(m).Type()
+
+	switch {
+	case isRawErrorHandler(t):
+		return func(c context.Context, w http.ResponseWriter, r *http.Request) {
+			v := reflect.ValueOf(m)
+			ret := v.Call([]reflect.Value{reflect.ValueOf(c), reflect.ValueOf(w), reflect.ValueOf(r)})
+			if err, _ := ret[0].Interface().(error); err != nil {
+				b.errorWriter()(c, w, r, err)
+			}
+		}, nil
+	case isTypedErrorHandler(t):
+		if err := b.validateSignature(m); err != nil {
+			return nil, err
+		}
+
+		d := newEndpointDescriptor(m)
+
+		return func(c context.Context, w http.ResponseWriter, r *http.Request) {
+			req, reqCodec, err := b.decodeReq(r, d)
+			if err != nil {
+				b.errorWriter()(c, w, r, NewError(http.StatusBadRequest, fmt.Errorf("could not decode request: %v", err)))
+				return
+			}
+
+			if validator, ok := req.(Validator); ok {
+				if err := validator.Validate(); err != nil {
+					b.errorWriter()(c, w, r, NewError(http.StatusBadRequest, fmt.Errorf("could not validate request: %v", err)))
+					return
+				}
+			}
+
+			c = contextWithRequest(c, r)
+			result, err := d.invoke(c, w, req)
+			if err != nil {
+				b.errorWriter()(c, w, r, err)
+				return
+			}
+
+			if s, ok := result.(Streamer); ok {
+				b.writeStream(c, w, s)
+				return
+			}
+
+			codec := b.responseCodec(r, reqCodec)
+
+			resp, _ := result.(*Response)
+			if resp == nil {
+				b.write(w, r, codec, NewResponse(nil, http.StatusOK))
+				return
+			}
+
+			b.write(w, r, codec, resp)
+		}, nil
+	default:
+		return nil, fmt.Errorf("incorrect error endpoint signature (must be func(ctx, w, r) error or func(ctx, w, req) (*http.Response, error))")
+	}
+}
+
+func isRawErrorHandler(t reflect.Type) bool {
+	return t.NumIn() == 3 && t.NumOut() == 1 &&
+		t.In(0).Implements(reflect.TypeOf((*context.Context)(nil)).Elem()) &&
+		t.In(1).Implements(reflect.TypeOf((*http.ResponseWriter)(nil)).Elem()) &&
+		t.In(2) == reflect.TypeOf((*http.Request)(nil)) &&
+		t.Out(0).Implements(reflect.TypeOf((*error)(nil)).Elem())
+}
+
+func isTypedErrorHandler(t reflect.Type) bool {
+	return t.NumIn() == 3 && t.NumOut() == 2 &&
+		t.In(2).Kind() == reflect.Ptr &&
+		(t.Out(0) == reflect.TypeOf(&Response{}) || t.Out(0).Implements(streamerType)) &&
+		t.Out(1).Implements(reflect.TypeOf((*error)(nil)).Elem())
+}
+
 func (b *BaseService) handlerFromMethod(m interface{}) (HandlerFunc, error) {
 	err := b.validateSignature(m)
 	if err != nil {
 		return nil, err
 	}
 
+	d := newEndpointDescriptor(m)
+
 	return func(c context.Context, w http.ResponseWriter, r *http.Request) {
-		req, err := b.decodeReq(r, m)
+		req, reqCodec, err := b.decodeReq(r, d)
 		if err != nil {
-			respond.WithJSON(
-				w, r,
+			b.write(
+				w, r, b.responseCodec(r, nil),
 				NewError(http.StatusBadRequest, fmt.Errorf("internal error: could not decode request: %v", err)),
 			)
 			return
@@ -89,28 +278,71 @@ func (b *BaseService) handlerFromMethod(m interface{}) (HandlerFunc, error) {
 		if validator, ok := interface{}(req).(Validator); ok {
 			err = validator.Validate()
 			if err != nil {
-				respond.WithJSON(
-					w, r,
+				b.write(
+					w, r, b.responseCodec(r, reqCodec),
 					NewError(http.StatusBadRequest, fmt.Errorf("could not validate request: %v", err)),
 				)
 				return
 			}
 		}
 
-		c = context.WithValue(c, contextReqKey, r)
-		v := reflect.ValueOf(m)
-
-		b.writeResponse(
-			w, r,
-			v.Call([]reflect.Value{
-				reflect.ValueOf(c),
-				reflect.ValueOf(w),
-				reflect.ValueOf(req),
-			}),
-		)
+		c = contextWithRequest(c, r)
+		result, err := d.invoke(c, w, req)
+		b.writeResult(c, w, r, reqCodec, result, err)
 	}, nil
 }
 
+// endpointDescriptor caches everything a registered endpoint needs to serve a
+// request, so that the reflection RegisterEndpoint performs once isn't
+// repeated on every call. invoke still calls the handler through
+// reflect.Value.Call, which is itself inherently reflective; what the
+// descriptor removes from the hot path is re-deriving reflect.ValueOf(m),
+// its reflect.Type and the request type on every request. A single
+// non-reflective call per request isn't reachable here without codegen or
+// generics, since method is accepted as interface{} (see
+// BenchmarkNaiveReflectDispatch/BenchmarkRegisterEndpointDispatch in
+// service_bench_test.go for the measured difference this caching makes)
+type endpointDescriptor struct {
+	reqType     reflect.Type
+	newReq      func() interface{}
+	invoke      func(c context.Context, w http.ResponseWriter, req interface{}) (interface{}, error)
+	hasResponse bool
+	bind        bool
+}
+
+func newEndpointDescriptor(m interface{}) *endpointDescriptor {
+	v := reflect.ValueOf(m)
+	t := v.Type()
+	reqType := t.In(2).Elem()
+
+	d := &endpointDescriptor{
+		reqType:     reqType,
+		newReq:      func() interface{} { return reflect.New(reqType).Interface() },
+		hasResponse: t.NumOut() == 2,
+		bind:        hasBindTags(reqType),
+	}
+
+	if !d.hasResponse {
+		d.invoke = func(c context.Context, w http.ResponseWriter, req interface{}) (interface{}, error) {
+			ret := v.Call([]reflect.Value{reflect.ValueOf(c), reflect.ValueOf(w), reflect.ValueOf(req)})
+			err, _ := ret[0].Interface().(error)
+			return nil, err
+		}
+		return d
+	}
+
+	d.invoke = func(c context.Context, w http.ResponseWriter, req interface{}) (interface{}, error) {
+		ret := v.Call([]reflect.Value{reflect.ValueOf(c), reflect.ValueOf(w), reflect.ValueOf(req)})
+		err, _ := ret[1].Interface().(error)
+		if ret[0].IsNil() {
+			return nil, err
+		}
+		return ret[0].Interface(), err
+	}
+
+	return d
+}
+
 func (b *BaseService) validateSignature(m interface{}) error {
 	t := reflect.ValueOf(m).Type()
 
@@ -129,8 +361,8 @@ func (b *BaseService) validateSignature(m interface{}) error {
 	}
 
 	if t.NumOut() == 2 {
-		if t.Out(0) != reflect.TypeOf(&Response{}) {
-			return fmt.Errorf("first ret value must be of type *kit/http/Response")
+		if t.Out(0) != reflect.TypeOf(&Response{}) && !t.Out(0).Implements(streamerType) {
+			return fmt.Errorf("first ret value must be of type *kit/http/Response or implement Streamer")
 		}
 
 		if !t.Out(1).Implements(reflect.TypeOf((*error)(nil)).Elem()) {
@@ -149,51 +381,71 @@ func (b *BaseService) validateSignature(m interface{}) error {
 	return nil
 }
 
-func (b *BaseService) decodeReq(r *http.Request, m interface{}) (interface{}, error) {
+func (b *BaseService) decodeReq(r *http.Request, d *endpointDescriptor) (interface{}, Codec, error) {
 	defer r.Body.Close()
 
-	v := reflect.ValueOf(m)
-	reqParamType := v.Type().In(2).Elem()
-	req := reflect.New(reqParamType).Interface()
+	req := d.newReq()
 
-	err := json.NewDecoder(r.Body).Decode(req)
-	if err != nil {
-		return nil, fmt.Errorf("error decoding json: %v", err)
+	codec := JSONCodec
+	if ct := contentType(r.Header.Get("Content-Type")); ct != "" {
+		c, ok := b.codecRegistry()[ct]
+		if !ok {
+			return nil, nil, fmt.Errorf("unsupported content type: %s", ct)
+		}
+		codec = c
+	}
+
+	if d.bind {
+		if err := bindRequest(r, codec, req); err != nil {
+			return nil, nil, err
+		}
+		return req, codec, nil
+	}
+
+	if err := codec.Decode(r.Body, req); err != nil {
+		return nil, nil, fmt.Errorf("error decoding request: %v", err)
 	}
 
-	return req, nil
+	return req, codec, nil
 }
 
-func (b *BaseService) writeResponse(w http.ResponseWriter, r *http.Request, ret []reflect.Value) {
-	if len(ret) == 1 {
-		if !ret[0].IsNil() {
-			b.writeError(w, r, ret[0].Interface())
-			return
-		}
-		respond.WithJSON(w, r, NewResponse(nil, http.StatusOK))
+func (b *BaseService) writeResult(c context.Context, w http.ResponseWriter, r *http.Request, reqCodec Codec, result interface{}, err error) {
+	if err != nil {
+		b.writeError(w, r, b.responseCodec(r, reqCodec), err)
 		return
 	}
 
-	if !ret[1].IsNil() {
-		b.writeError(w, r, ret[1].Interface())
+	if s, ok := result.(Streamer); ok {
+		b.writeStream(c, w, s)
 		return
 	}
 
-	if ret[0].IsNil() {
-		respond.WithJSON(w, r, NewResponse(nil, http.StatusOK))
+	codec := b.responseCodec(r, reqCodec)
+
+	resp, _ := result.(*Response)
+	if resp == nil {
+		b.write(w, r, codec, NewResponse(nil, http.StatusOK))
 		return
 	}
 
-	resp := ret[0].Interface().(*Response)
-	respond.WithJSON(w, r, resp)
+	b.write(w, r, codec, resp)
+}
+
+// writeStream hands the response over to s instead of encoding it, using c
+// (which carries the same cancellation and values as the request's handler
+// context, see contextWithRequest) so Stream observes client disconnects and
+// has access to e.g. LastEventID
+func (b *BaseService) writeStream(c context.Context, w http.ResponseWriter, s Streamer) {
+	w.Header().Set("Content-Type", s.ContentType())
+	_ = s.Stream(c, w)
 }
 
-func (b *BaseService) writeError(w http.ResponseWriter, r *http.Request, e interface{}) {
-	if _, ok := e.(*Error); ok {
-		respond.WithJSON(w, r, e)
+func (b *BaseService) writeError(w http.ResponseWriter, r *http.Request, codec Codec, err error) {
+	if e, ok := err.(*Error); ok {
+		b.write(w, r, codec, e)
 		return
 	}
-	respond.WithJSON(w, r, NewError(http.StatusInternalServerError, e.(error)))
+	b.write(w, r, codec, NewError(http.StatusInternalServerError, err))
 }
 
 // Endpoints returns all registered endpoints
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type benchRequest struct {
+	Name string `json:"name"`
+}
+
+func benchHandler(c context.Context, w http.ResponseWriter, req *benchRequest) (*Response, error) {
+	return NewResponse(req.Name, http.StatusOK), nil
+}
+
+// BenchmarkRegisterEndpointDispatch exercises the full decode -> invoke ->
+// encode path for a registered endpoint, to track allocs/op on the
+// descriptor-cached dispatch path
+func BenchmarkRegisterEndpointDispatch(b *testing.B) {
+	var svc BaseService
+	if err := svc.RegisterEndpoint("POST", "/bench", benchHandler); err != nil {
+		b.Fatal(err)
+	}
+
+	h := svc.Endpoints()["/bench"].Handler
+	body := []byte(`{"name":"kit"}`)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		r := httptest.NewRequest("POST", "/bench", bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		h(context.Background(), w, r)
+	}
+}
+
+// handlerFromDescriptor is handlerFromMethod's closure, lifted out so a
+// benchmark can supply its own endpointDescriptor instead of the one
+// RegisterEndpoint builds. Every step besides d.invoke - content-type
+// parsing, codec lookup, decode, validation, Accept negotiation and response
+// encoding - goes through the same BaseService helpers the real handler
+// uses, so swapping d.invoke is the only thing that can move the needle
+// between BenchmarkRegisterEndpointDispatch and BenchmarkNaiveReflectDispatch
+func handlerFromDescriptor(b *BaseService, d *endpointDescriptor) HandlerFunc {
+	return func(c context.Context, w http.ResponseWriter, r *http.Request) {
+		req, reqCodec, err := b.decodeReq(r, d)
+		if err != nil {
+			b.write(
+				w, r, b.responseCodec(r, nil),
+				NewError(http.StatusBadRequest, err),
+			)
+			return
+		}
+
+		if validator, ok := interface{}(req).(Validator); ok {
+			if err := validator.Validate(); err != nil {
+				b.write(w, r, b.responseCodec(r, reqCodec), NewError(http.StatusBadRequest, err))
+				return
+			}
+		}
+
+		c = contextWithRequest(c, r)
+		result, err := d.invoke(c, w, req)
+		b.writeResult(c, w, r, reqCodec, result, err)
+	}
+}
+
+// BenchmarkNaiveReflectDispatch is the baseline BenchmarkRegisterEndpointDispatch
+// is compared against. It runs through the exact same decode/validate/encode
+// helpers (via handlerFromDescriptor), but its invoke re-derives
+// reflect.ValueOf(benchHandler) on every call instead of closing over it once
+// like newEndpointDescriptor does, reproducing the pre-descriptor dispatch
+// path this package used to take. The allocs/op delta between the two
+// benchmarks therefore isolates the descriptor-caching win, not a difference
+// in how much work each one does
+func BenchmarkNaiveReflectDispatch(b *testing.B) {
+	var svc BaseService
+
+	d := newEndpointDescriptor(benchHandler)
+	d.invoke = func(c context.Context, w http.ResponseWriter, req interface{}) (interface{}, error) {
+		v := reflect.ValueOf(benchHandler)
+		ret := v.Call([]reflect.Value{reflect.ValueOf(c), reflect.ValueOf(w), reflect.ValueOf(req)})
+		err, _ := ret[1].Interface().(error)
+		if ret[0].IsNil() {
+			return nil, err
+		}
+		return ret[0].Interface(), err
+	}
+
+	h := handlerFromDescriptor(&svc, d)
+	body := []byte(`{"name":"kit"}`)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		r := httptest.NewRequest("POST", "/bench", bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		h(context.Background(), w, r)
+	}
+}
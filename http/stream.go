@@ -0,0 +1,178 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// Streamer is an alternative endpoint return type for responses that can't be
+// expressed as a single JSON body: SSE, NDJSON, or a raw chunked download.
+// Returning a Streamer (in place of *Response) as the first return value
+// of a RegisterEndpoint/RegisterErrorEndpoint method hands the response over
+// to Stream instead of having BaseService encode it
+type Streamer interface {
+	// ContentType is written as the response's Content-Type header before
+	// Stream is called
+	ContentType() string
+	// Stream writes the response body to w. ctx carries the same
+	// cancellation and values as the handler's context (see
+	// contextWithRequest), and is done when the client disconnects, so
+	// producers should select on ctx.Done() and stop
+	Stream(ctx context.Context, w http.ResponseWriter) error
+}
+
+var streamerType = reflect.TypeOf((*Streamer)(nil)).Elem()
+
+func flush(w http.ResponseWriter) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+type rawStream struct {
+	r           io.Reader
+	contentType string
+}
+
+// RawStream returns a Streamer that copies r to the response as it is read,
+// flushing after every chunk, with contentType as the Content-Type
+func RawStream(r io.Reader, contentType string) Streamer {
+	return &rawStream{r: r, contentType: contentType}
+}
+
+func (s *rawStream) ContentType() string { return s.contentType }
+
+func (s *rawStream) Stream(ctx context.Context, w http.ResponseWriter) error {
+	buf := make([]byte, 32*1024)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		n, err := s.r.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			flush(w)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+type ndjsonStream struct {
+	ch <-chan interface{}
+}
+
+// NDJSONStream returns a Streamer that JSON-encodes every value received on
+// ch as its own line, flushing after each one, until ch is closed or the
+// client disconnects
+func NDJSONStream(ch <-chan interface{}) Streamer {
+	return &ndjsonStream{ch: ch}
+}
+
+func (s *ndjsonStream) ContentType() string { return "application/x-ndjson" }
+
+func (s *ndjsonStream) Stream(ctx context.Context, w http.ResponseWriter) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case v, ok := <-s.ch:
+			if !ok {
+				return nil
+			}
+			if err := JSONCodec.Encode(w, v); err != nil {
+				return err
+			}
+			flush(w)
+		}
+	}
+}
+
+// Event is a single server-sent event written by SSEStream. ID and Name are
+// optional; Data is JSON-encoded as the event's data
+type Event struct {
+	ID   string
+	Name string
+	Data interface{}
+}
+
+type sseStream struct {
+	ch        <-chan Event
+	heartbeat time.Duration
+}
+
+// SSEStream returns a Streamer that frames every Event received on ch as
+// id:/event:/data: lines per the server-sent events spec, sending a comment
+// heartbeat every interval to keep idle connections alive. A zero interval
+// disables heartbeats. Stream ends when ch is closed or the client
+// disconnects
+func SSEStream(ch <-chan Event, heartbeat time.Duration) Streamer {
+	return &sseStream{ch: ch, heartbeat: heartbeat}
+}
+
+func (s *sseStream) ContentType() string { return "text/event-stream" }
+
+func (s *sseStream) Stream(ctx context.Context, w http.ResponseWriter) error {
+	var tick <-chan time.Time
+	if s.heartbeat > 0 {
+		t := time.NewTicker(s.heartbeat)
+		defer t.Stop()
+		tick = t.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return err
+			}
+			flush(w)
+		case e, ok := <-s.ch:
+			if !ok {
+				return nil
+			}
+			if err := writeSSEEvent(w, e); err != nil {
+				return err
+			}
+			flush(w)
+		}
+	}
+}
+
+func writeSSEEvent(w http.ResponseWriter, e Event) error {
+	payload, err := json.Marshal(e.Data)
+	if err != nil {
+		return err
+	}
+
+	if e.ID != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", e.ID); err != nil {
+			return err
+		}
+	}
+	if e.Name != "" {
+		if _, err := fmt.Fprintf(w, "event: %s\n", e.Name); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "data: %s\n\n", payload)
+	return err
+}
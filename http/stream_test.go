@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNDJSONStreamWritesOneLinePerValue(t *testing.T) {
+	ch := make(chan interface{}, 2)
+	ch <- map[string]string{"a": "1"}
+	ch <- map[string]string{"a": "2"}
+	close(ch)
+
+	s := NDJSONStream(ch)
+	w := httptest.NewRecorder()
+
+	if err := s.Stream(context.Background(), w); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), w.Body.String())
+	}
+}
+
+func TestSSEStreamFramesEvents(t *testing.T) {
+	ch := make(chan Event, 1)
+	ch <- Event{ID: "1", Name: "tick", Data: "hello"}
+	close(ch)
+
+	s := SSEStream(ch, 0)
+	w := httptest.NewRecorder()
+
+	if err := s.Stream(context.Background(), w); err != nil {
+		t.Fatal(err)
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{"id: 1\n", "event: tick\n", `data: "hello"`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected body to contain %q, got %q", want, body)
+		}
+	}
+}
+
+func TestSSEStreamStopsOnContextCancel(t *testing.T) {
+	ch := make(chan Event)
+	s := SSEStream(ch, 0)
+	w := httptest.NewRecorder()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := s.Stream(ctx, w); err == nil {
+		t.Fatal("expected Stream to return an error when the context is already cancelled")
+	}
+}
+
+func TestRawStreamCopiesReader(t *testing.T) {
+	s := RawStream(strings.NewReader("payload"), "text/plain")
+	w := httptest.NewRecorder()
+
+	if err := s.Stream(context.Background(), w); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Body.String(); got != "payload" {
+		t.Fatalf("expected body %q, got %q", "payload", got)
+	}
+	if s.ContentType() != "text/plain" {
+		t.Fatalf("expected content type text/plain, got %q", s.ContentType())
+	}
+}
+
+func TestSSEStreamHeartbeat(t *testing.T) {
+	ch := make(chan Event)
+	s := SSEStream(ch, 5*time.Millisecond)
+	w := httptest.NewRecorder()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_ = s.Stream(ctx, w)
+
+	if !strings.Contains(w.Body.String(), ": heartbeat") {
+		t.Fatalf("expected at least one heartbeat, got %q", w.Body.String())
+	}
+}
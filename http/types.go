@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+)
+
+// HandlerFunc is an extension of http.HandlerFunc which only adds
+// context.Context as first parameter, the rest stays the same
+type HandlerFunc func(c context.Context, w http.ResponseWriter, r *http.Request)
+
+// Adapter wraps a HandlerFunc with additional behaviour
+type Adapter func(HandlerFunc) HandlerFunc
+
+// AdaptHandlerFunc applies the given adapters to h, in the order they were
+// provided, and returns the resulting HandlerFunc
+func AdaptHandlerFunc(h HandlerFunc, a ...Adapter) HandlerFunc {
+	for i := len(a) - 1; i >= 0; i-- {
+		h = a[i](h)
+	}
+	return h
+}
+
+// Endpoint represents a single registered service endpoint
+type Endpoint struct {
+	Methods []string
+	Handler HandlerFunc
+
+	reqType reflect.Type
+	doc     *endpointDoc
+}
+
+// Endpoints maps a routing path to its Endpoint
+type Endpoints map[string]*Endpoint
+
+type contextKey int
+
+const (
+	contextReqKey contextKey = iota
+	contextLastEventIDKey
+)
+
+// RequestFromContext returns the *http.Request stored in c by BaseService,
+// if any
+func RequestFromContext(c context.Context) (*http.Request, bool) {
+	r, ok := c.Value(contextReqKey).(*http.Request)
+	return r, ok
+}
+
+// contextWithRequest returns a copy of c carrying r, and, if the client set
+// one, its Last-Event-ID header (see LastEventID)
+func contextWithRequest(c context.Context, r *http.Request) context.Context {
+	c = context.WithValue(c, contextReqKey, r)
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		c = context.WithValue(c, contextLastEventIDKey, id)
+	}
+	return c
+}
+
+// LastEventID returns the Last-Event-ID header sent by the client when
+// reconnecting to an SSE stream, if any
+func LastEventID(c context.Context) (string, bool) {
+	id, ok := c.Value(contextLastEventIDKey).(string)
+	return id, ok
+}